@@ -0,0 +1,403 @@
+package main
+
+import (
+	"math"
+	"syscall/js"
+)
+
+// body shape kinds for goBodyCreate.
+const (
+	shapeCircle  = "circle"
+	shapeSegment = "segment"
+)
+
+// body is a single rigid body in the world: a circle, or a static line
+// segment (infinite mass, used for walls/floors).
+type body struct {
+	shape string
+
+	mass    float64
+	invMass float64
+
+	pos Vector2D
+	vel Vector2D
+
+	radius float64 // circle
+
+	segA, segB Vector2D // segment endpoints
+}
+
+func newCircleBody(mass, x, y, radius float64) *body {
+	b := &body{shape: shapeCircle, mass: mass, pos: Vector2D{X: x, Y: y}, radius: radius}
+	if mass > 0 {
+		b.invMass = 1 / mass
+	}
+	return b
+}
+
+func newSegmentBody(ax, ay, bx, by float64) *body {
+	return &body{shape: shapeSegment, segA: Vector2D{X: ax, Y: ay}, segB: Vector2D{X: bx, Y: by}}
+}
+
+// contact is a single circle-circle or circle-segment contact point
+// between bodies a and b (b may be the static segment side), carrying
+// the accumulated normal/tangent impulses for warm starting across
+// Gauss-Seidel iterations within a step.
+type contact struct {
+	a, b                  *body
+	normal                Vector2D
+	point                 Vector2D
+	penetration           float64
+	accNormal, accTangent float64
+
+	// restitutionBias is the target normal relative velocity the GS
+	// solver drives toward: -e * min(0, vn_initial), computed once from
+	// the pre-solve approach velocity. Recomputing (1+e) from the
+	// current velocity on every pass would geometrically damp it to
+	// zero as iters grows, so it's fixed at contact creation instead.
+	restitutionBias float64
+}
+
+// world holds every body and runs the impulse-based contact solver.
+type world struct {
+	gravity     Vector2D
+	restitution float64
+	friction    float64
+	bias        float64 // Baumgarte position-correction factor
+	slop        float64 // penetration allowance before correction kicks in
+
+	bodies []*body
+}
+
+func newWorld(gravityX, gravityY, restitution, friction, bias, slop float64) *world {
+	return &world{
+		gravity:     Vector2D{X: gravityX, Y: gravityY},
+		restitution: restitution,
+		friction:    friction,
+		bias:        bias,
+		slop:        slop,
+	}
+}
+
+// circle2circleQuery tests two circle bodies for overlap, returning the
+// contact (normal pointing from a to b) and whether they actually
+// overlap.
+func circle2circleQuery(a, b *body) (contact, bool) {
+	delta := Vector2D{X: b.pos.X - a.pos.X, Y: b.pos.Y - a.pos.Y}
+	distSqr := delta.X*delta.X + delta.Y*delta.Y
+	radiusSum := a.radius + b.radius
+
+	if distSqr >= radiusSum*radiusSum {
+		return contact{}, false
+	}
+
+	dist := math.Sqrt(distSqr)
+	var normal Vector2D
+	if dist == 0 {
+		normal = Vector2D{X: 1, Y: 0}
+	} else {
+		normal = Vector2D{X: delta.X / dist, Y: delta.Y / dist}
+	}
+
+	pos := Vector2D{
+		X: a.pos.X + delta.X*0.5 + normal.X*(a.radius-0.5*radiusSum),
+		Y: a.pos.Y + delta.Y*0.5 + normal.Y*(a.radius-0.5*radiusSum),
+	}
+
+	return contact{
+		a: a, b: b,
+		normal:      normal,
+		point:       pos,
+		penetration: dist - radiusSum,
+	}, true
+}
+
+// circle2segmentQuery tests a circle against a static line segment,
+// returning the contact with the normal pointing away from the segment
+// toward the circle.
+func circle2segmentQuery(c, seg *body) (contact, bool) {
+	ab := Vector2D{X: seg.segB.X - seg.segA.X, Y: seg.segB.Y - seg.segA.Y}
+	abLenSqr := ab.X*ab.X + ab.Y*ab.Y
+
+	t := 0.0
+	if abLenSqr > 0 {
+		t = ((c.pos.X-seg.segA.X)*ab.X + (c.pos.Y-seg.segA.Y)*ab.Y) / abLenSqr
+		if t < 0 {
+			t = 0
+		} else if t > 1 {
+			t = 1
+		}
+	}
+
+	closest := Vector2D{X: seg.segA.X + t*ab.X, Y: seg.segA.Y + t*ab.Y}
+	delta := Vector2D{X: c.pos.X - closest.X, Y: c.pos.Y - closest.Y}
+	distSqr := delta.X*delta.X + delta.Y*delta.Y
+
+	if distSqr >= c.radius*c.radius {
+		return contact{}, false
+	}
+
+	dist := math.Sqrt(distSqr)
+	var normal Vector2D
+	if dist == 0 {
+		normal = Vector2D{X: 1, Y: 0}
+	} else {
+		normal = Vector2D{X: delta.X / dist, Y: delta.Y / dist}
+	}
+
+	return contact{
+		a: seg, b: c,
+		normal:      normal,
+		point:       closest,
+		penetration: dist - c.radius,
+	}, true
+}
+
+// findContacts builds the contact list for this step by checking every
+// pair of bodies once, and stamps each with the restitution bias derived
+// from its pre-solve approach velocity.
+func (w *world) findContacts() []contact {
+	var contacts []contact
+	for i := 0; i < len(w.bodies); i++ {
+		for j := i + 1; j < len(w.bodies); j++ {
+			a, b := w.bodies[i], w.bodies[j]
+
+			switch {
+			case a.shape == shapeCircle && b.shape == shapeCircle:
+				if c, ok := circle2circleQuery(a, b); ok {
+					contacts = append(contacts, c)
+				}
+			case a.shape == shapeCircle && b.shape == shapeSegment:
+				if c, ok := circle2segmentQuery(a, b); ok {
+					contacts = append(contacts, c)
+				}
+			case a.shape == shapeSegment && b.shape == shapeCircle:
+				if c, ok := circle2segmentQuery(b, a); ok {
+					contacts = append(contacts, c)
+				}
+			}
+		}
+	}
+
+	for i := range contacts {
+		c := &contacts[i]
+		rv := Vector2D{X: c.b.vel.X - c.a.vel.X, Y: c.b.vel.Y - c.a.vel.Y}
+		velAlongNormal := rv.X*c.normal.X + rv.Y*c.normal.Y
+		c.restitutionBias = -w.restitution * minF(0, velAlongNormal)
+	}
+
+	return contacts
+}
+
+// resolveContact applies one Gauss-Seidel normal+friction impulse pass to
+// a single contact, accumulating impulses so repeated passes converge
+// toward the fixed restitutionBias rather than re-deriving it from the
+// current velocity (which would geometrically damp it to zero over
+// iterations).
+func (w *world) resolveContact(c *contact) {
+	a, b := c.a, c.b
+	invMassSum := a.invMass + b.invMass
+	if invMassSum == 0 {
+		return
+	}
+
+	rv := Vector2D{X: b.vel.X - a.vel.X, Y: b.vel.Y - a.vel.Y}
+	velAlongNormal := rv.X*c.normal.X + rv.Y*c.normal.Y
+
+	jn := -(velAlongNormal - c.restitutionBias) / invMassSum
+	newAccNormal := c.accNormal + jn
+	if newAccNormal < 0 {
+		newAccNormal = 0
+	}
+	jn = newAccNormal - c.accNormal
+	c.accNormal = newAccNormal
+
+	a.vel.X -= jn * c.normal.X * a.invMass
+	a.vel.Y -= jn * c.normal.Y * a.invMass
+	b.vel.X += jn * c.normal.X * b.invMass
+	b.vel.Y += jn * c.normal.Y * b.invMass
+
+	// Friction along the tangent, clamped to the Coulomb cone scaled by
+	// the accumulated normal impulse.
+	rv = Vector2D{X: b.vel.X - a.vel.X, Y: b.vel.Y - a.vel.Y}
+	tangent := Vector2D{X: -c.normal.Y, Y: c.normal.X}
+	velAlongTangent := rv.X*tangent.X + rv.Y*tangent.Y
+
+	jt := -velAlongTangent / invMassSum
+	maxFriction := w.friction * c.accNormal
+	newAccTangent := clampF(c.accTangent+jt, -maxFriction, maxFriction)
+	jt = newAccTangent - c.accTangent
+	c.accTangent = newAccTangent
+
+	a.vel.X -= jt * tangent.X * a.invMass
+	a.vel.Y -= jt * tangent.Y * a.invMass
+	b.vel.X += jt * tangent.X * b.invMass
+	b.vel.Y += jt * tangent.Y * b.invMass
+}
+
+// positionalCorrection nudges overlapping bodies apart by the penetration
+// beyond an allowed slop, split by inverse mass, so resolved contacts
+// don't visibly sink into each other.
+func (w *world) positionalCorrection(c *contact) {
+	a, b := c.a, c.b
+	invMassSum := a.invMass + b.invMass
+	if invMassSum == 0 {
+		return
+	}
+
+	correctionMag := w.bias * maxF(0, -c.penetration-w.slop) / invMassSum
+	correction := Vector2D{X: correctionMag * c.normal.X, Y: correctionMag * c.normal.Y}
+
+	a.pos.X -= correction.X * a.invMass
+	a.pos.Y -= correction.Y * a.invMass
+	b.pos.X += correction.X * b.invMass
+	b.pos.Y += correction.Y * b.invMass
+}
+
+func clampF(x, lo, hi float64) float64 {
+	if x < lo {
+		return lo
+	}
+	if x > hi {
+		return hi
+	}
+	return x
+}
+
+func maxF(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minF(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// step integrates velocities under gravity, then runs `iters`
+// Gauss-Seidel passes resolving every contact, then applies positional
+// correction for any remaining penetration.
+func (w *world) step(dt float64, iters int) []contact {
+	for _, b := range w.bodies {
+		if b.invMass > 0 {
+			b.vel.X += w.gravity.X * dt
+			b.vel.Y += w.gravity.Y * dt
+		}
+	}
+
+	contacts := w.findContacts()
+
+	for i := 0; i < iters; i++ {
+		for k := range contacts {
+			w.resolveContact(&contacts[k])
+		}
+	}
+
+	for _, b := range w.bodies {
+		if b.invMass > 0 {
+			b.pos.X += b.vel.X * dt
+			b.pos.Y += b.vel.Y * dt
+		}
+	}
+
+	for k := range contacts {
+		w.positionalCorrection(&contacts[k])
+	}
+
+	return contacts
+}
+
+var theWorld = newWorld(0, -9.81, 0.3, 0.4, 0.2, 0.01)
+
+// goBodyCreate adds a circle or static segment body to the world and
+// returns its index (used as a handle by goWorldStep's snapshot).
+//
+// Args for shape "circle": "circle", mass, x, y, radius.
+// Args for shape "segment": "segment", ax, ay, bx, by.
+func goBodyCreate(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]interface{}{
+			"error": "Invalid arguments. Expected: shape, ...",
+		})
+	}
+
+	shape := args[0].String()
+
+	switch shape {
+	case shapeCircle:
+		if len(args) != 5 {
+			return js.ValueOf(map[string]interface{}{
+				"error": "Invalid arguments for circle. Expected: shape, mass, x, y, radius",
+			})
+		}
+		b := newCircleBody(args[1].Float(), args[2].Float(), args[3].Float(), args[4].Float())
+		theWorld.bodies = append(theWorld.bodies, b)
+	case shapeSegment:
+		if len(args) != 5 {
+			return js.ValueOf(map[string]interface{}{
+				"error": "Invalid arguments for segment. Expected: shape, ax, ay, bx, by",
+			})
+		}
+		b := newSegmentBody(args[1].Float(), args[2].Float(), args[3].Float(), args[4].Float())
+		theWorld.bodies = append(theWorld.bodies, b)
+	default:
+		return js.ValueOf(map[string]interface{}{
+			"error": "Unknown shape: " + shape,
+		})
+	}
+
+	return js.ValueOf(map[string]interface{}{
+		"success": true,
+		"id":      len(theWorld.bodies) - 1,
+	})
+}
+
+// goWorldStep advances the world by dt with `iters` Gauss-Seidel contact
+// passes and returns a snapshot of every body's position, suitable for a
+// caller to use to position HTML/canvas elements (see updateDOMElement),
+// plus the contact points resolved this step.
+//
+// Args: dt (float), iters (int).
+func goWorldStep(this js.Value, args []js.Value) interface{} {
+	if len(args) != 2 {
+		return js.ValueOf(map[string]interface{}{
+			"error": "Invalid arguments. Expected: dt, iters",
+		})
+	}
+
+	dt := args[0].Float()
+	iters := args[1].Int()
+
+	contacts := theWorld.step(dt, iters)
+
+	snapshot := make([]interface{}, len(theWorld.bodies))
+	for i, b := range theWorld.bodies {
+		snapshot[i] = map[string]interface{}{
+			"shape": b.shape,
+			"x":     b.pos.X,
+			"y":     b.pos.Y,
+			"vx":    b.vel.X,
+			"vy":    b.vel.Y,
+		}
+	}
+
+	contactSnapshot := make([]interface{}, len(contacts))
+	for i, c := range contacts {
+		contactSnapshot[i] = map[string]interface{}{
+			"x":           c.point.X,
+			"y":           c.point.Y,
+			"normalX":     c.normal.X,
+			"normalY":     c.normal.Y,
+			"penetration": c.penetration,
+		}
+	}
+
+	return js.ValueOf(map[string]interface{}{
+		"bodies":   snapshot,
+		"contacts": contactSnapshot,
+	})
+}
@@ -0,0 +1,294 @@
+package main
+
+import "syscall/js"
+
+// boundary modes for fluidSolver.setBnd
+const (
+	fluidBoundaryNoSlip   = 0
+	fluidBoundaryPeriodic = 1
+)
+
+// fluidSolver holds the scratch buffers for a Stam-style stable fluids
+// simulation on an (N+2)x(N+2) grid with a one-cell border. Buffers are
+// reused across calls to goFluidStep so stepping the simulation does not
+// allocate per frame.
+type fluidSolver struct {
+	n        int
+	diff     float64
+	visc     float64
+	boundary int
+	dens     []float64
+	densPrev []float64
+	u, v     []float64
+	u0, v0   []float64
+}
+
+// global solver instance, (re)created on every call to goFluidInit.
+var theFluidSolver *fluidSolver
+
+func newFluidSolver(n int, diff, visc float64, boundary int) *fluidSolver {
+	size := (n + 2) * (n + 2)
+	return &fluidSolver{
+		n:        n,
+		diff:     diff,
+		visc:     visc,
+		boundary: boundary,
+		dens:     make([]float64, size),
+		densPrev: make([]float64, size),
+		u:        make([]float64, size),
+		v:        make([]float64, size),
+		u0:       make([]float64, size),
+		v0:       make([]float64, size),
+	}
+}
+
+func (f *fluidSolver) idx(i, j int) int {
+	return i + (f.n+2)*j
+}
+
+// addSource adds dt-scaled source values into x.
+func (f *fluidSolver) addSource(x, s []float64, dt float64) {
+	for k := range x {
+		x[k] += dt * s[k]
+	}
+}
+
+// setBnd enforces boundary conditions on a field. b selects how the field
+// behaves across walls: b==1 reflects the x-velocity, b==2 reflects the
+// y-velocity, b==0 (e.g. density) is copied straight across.
+func (f *fluidSolver) setBnd(b int, x []float64) {
+	n := f.n
+
+	if f.boundary == fluidBoundaryPeriodic {
+		for i := 1; i <= n; i++ {
+			x[f.idx(0, i)] = x[f.idx(n, i)]
+			x[f.idx(n+1, i)] = x[f.idx(1, i)]
+			x[f.idx(i, 0)] = x[f.idx(i, n)]
+			x[f.idx(i, n+1)] = x[f.idx(i, 1)]
+		}
+	} else {
+		for i := 1; i <= n; i++ {
+			if b == 1 {
+				x[f.idx(0, i)] = -x[f.idx(1, i)]
+				x[f.idx(n+1, i)] = -x[f.idx(n, i)]
+			} else {
+				x[f.idx(0, i)] = x[f.idx(1, i)]
+				x[f.idx(n+1, i)] = x[f.idx(n, i)]
+			}
+
+			if b == 2 {
+				x[f.idx(i, 0)] = -x[f.idx(i, 1)]
+				x[f.idx(i, n+1)] = -x[f.idx(i, n)]
+			} else {
+				x[f.idx(i, 0)] = x[f.idx(i, 1)]
+				x[f.idx(i, n+1)] = x[f.idx(i, n)]
+			}
+		}
+	}
+
+	x[f.idx(0, 0)] = 0.5 * (x[f.idx(1, 0)] + x[f.idx(0, 1)])
+	x[f.idx(0, n+1)] = 0.5 * (x[f.idx(1, n+1)] + x[f.idx(0, n)])
+	x[f.idx(n+1, 0)] = 0.5 * (x[f.idx(n, 0)] + x[f.idx(n+1, 1)])
+	x[f.idx(n+1, n+1)] = 0.5 * (x[f.idx(n, n+1)] + x[f.idx(n+1, n)])
+}
+
+// linSolve solves c*x - a*(sum of the four neighbours of x) = x0 with
+// Gauss-Seidel relaxation; with c = 1+4a this is (I - a*Laplacian) x = x0.
+func (f *fluidSolver) linSolve(b int, x, x0 []float64, a, c float64, iters int) {
+	n := f.n
+	for k := 0; k < iters; k++ {
+		for j := 1; j <= n; j++ {
+			for i := 1; i <= n; i++ {
+				x[f.idx(i, j)] = (x0[f.idx(i, j)] + a*(x[f.idx(i-1, j)]+x[f.idx(i+1, j)]+x[f.idx(i, j-1)]+x[f.idx(i, j+1)])) / c
+			}
+		}
+		f.setBnd(b, x)
+	}
+}
+
+func (f *fluidSolver) diffuse(b int, x, x0 []float64, diff, dt float64, iters int) {
+	a := dt * diff * float64(f.n) * float64(f.n)
+	f.linSolve(b, x, x0, a, 1+4*a, iters)
+}
+
+// advect traces each cell center backward through the velocity field and
+// bilinearly interpolates the value it lands on out of d0.
+func (f *fluidSolver) advect(b int, d, d0, u, v []float64, dt float64) {
+	n := f.n
+	dt0 := dt * float64(n)
+
+	for j := 1; j <= n; j++ {
+		for i := 1; i <= n; i++ {
+			x := float64(i) - dt0*u[f.idx(i, j)]
+			y := float64(j) - dt0*v[f.idx(i, j)]
+
+			if x < 0.5 {
+				x = 0.5
+			}
+			if x > float64(n)+0.5 {
+				x = float64(n) + 0.5
+			}
+			i0 := int(x)
+			i1 := i0 + 1
+
+			if y < 0.5 {
+				y = 0.5
+			}
+			if y > float64(n)+0.5 {
+				y = float64(n) + 0.5
+			}
+			j0 := int(y)
+			j1 := j0 + 1
+
+			s1 := x - float64(i0)
+			s0 := 1 - s1
+			t1 := y - float64(j0)
+			t0 := 1 - t1
+
+			d[f.idx(i, j)] = s0*(t0*d0[f.idx(i0, j0)]+t1*d0[f.idx(i0, j1)]) +
+				s1*(t0*d0[f.idx(i1, j0)]+t1*d0[f.idx(i1, j1)])
+		}
+	}
+	f.setBnd(b, d)
+}
+
+// project enforces incompressibility (div u == 0) via a Poisson solve,
+// reusing u0/v0 as scratch for the divergence and pressure fields.
+func (f *fluidSolver) project(u, v, p, div []float64, iters int) {
+	n := f.n
+	fn := float64(n)
+
+	for j := 1; j <= n; j++ {
+		for i := 1; i <= n; i++ {
+			div[f.idx(i, j)] = -0.5 * (u[f.idx(i+1, j)] - u[f.idx(i-1, j)] + v[f.idx(i, j+1)] - v[f.idx(i, j-1)]) / fn
+			p[f.idx(i, j)] = 0
+		}
+	}
+	f.setBnd(0, div)
+	f.setBnd(0, p)
+
+	f.linSolve(0, p, div, 1, 4, iters)
+
+	for j := 1; j <= n; j++ {
+		for i := 1; i <= n; i++ {
+			u[f.idx(i, j)] -= 0.5 * fn * (p[f.idx(i+1, j)] - p[f.idx(i-1, j)])
+			v[f.idx(i, j)] -= 0.5 * fn * (p[f.idx(i, j+1)] - p[f.idx(i, j-1)])
+		}
+	}
+	f.setBnd(1, u)
+	f.setBnd(2, v)
+}
+
+// velStep advances the velocity field u,v by dt given source terms u0,v0.
+func (f *fluidSolver) velStep(dt float64, iters int) {
+	f.addSource(f.u, f.u0, dt)
+	f.addSource(f.v, f.v0, dt)
+
+	f.u0, f.u = f.u, f.u0
+	f.diffuse(1, f.u, f.u0, f.visc, dt, iters)
+	f.v0, f.v = f.v, f.v0
+	f.diffuse(2, f.v, f.v0, f.visc, dt, iters)
+
+	f.project(f.u, f.v, f.u0, f.v0, iters)
+
+	f.u0, f.u = f.u, f.u0
+	f.v0, f.v = f.v, f.v0
+	f.advect(1, f.u, f.u0, f.u0, f.v0, dt)
+	f.advect(2, f.v, f.v0, f.u0, f.v0, dt)
+
+	f.project(f.u, f.v, f.u0, f.v0, iters)
+}
+
+// densStep advances the density field by dt through the current velocity.
+func (f *fluidSolver) densStep(dt float64, iters int) {
+	f.addSource(f.dens, f.densPrev, dt)
+	f.densPrev, f.dens = f.dens, f.densPrev
+	f.diffuse(0, f.dens, f.densPrev, f.diff, dt, iters)
+	f.densPrev, f.dens = f.dens, f.densPrev
+	f.advect(0, f.dens, f.densPrev, f.u, f.v, dt)
+}
+
+// goFluidInit allocates (or reallocates) the fluid solver's scratch
+// buffers for a given grid resolution. Call it once before goFluidStep,
+// and again whenever n, diff, visc or boundary need to change.
+//
+// Args: n (int), diff, visc (float), boundary (int, 0 = no-slip walls,
+// 1 = periodic).
+func goFluidInit(this js.Value, args []js.Value) interface{} {
+	if len(args) != 4 {
+		return js.ValueOf(map[string]interface{}{
+			"error": "Invalid arguments. Expected: n, diff, visc, boundary",
+		})
+	}
+
+	n := args[0].Int()
+	diff := args[1].Float()
+	visc := args[2].Float()
+	boundary := args[3].Int()
+
+	theFluidSolver = newFluidSolver(n, diff, visc, boundary)
+
+	return js.ValueOf(map[string]interface{}{
+		"success": true,
+		"n":       n,
+	})
+}
+
+// goFluidStep runs one Stam stable-fluids step (vel_step then dens_step)
+// and returns the updated density and velocity fields, flattened in
+// row-major (N+2)x(N+2) order.
+//
+// Args: dt (float), iters (int), densitySource, uSource, vSource
+// (flattened (N+2)x(N+2) Float64Arrays, added in as this frame's sources).
+func goFluidStep(this js.Value, args []js.Value) interface{} {
+	if theFluidSolver == nil {
+		return js.ValueOf(map[string]interface{}{
+			"error": "goFluidInit must be called before goFluidStep",
+		})
+	}
+	if len(args) != 5 {
+		return js.ValueOf(map[string]interface{}{
+			"error": "Invalid arguments. Expected: dt, iters, densitySource, uSource, vSource",
+		})
+	}
+
+	f := theFluidSolver
+	dt := args[0].Float()
+	iters := args[1].Int()
+
+	copyJSFloat64Array(args[2], f.densPrev)
+	copyJSFloat64Array(args[3], f.u0)
+	copyJSFloat64Array(args[4], f.v0)
+
+	f.velStep(dt, iters)
+	f.densStep(dt, iters)
+
+	return js.ValueOf(map[string]interface{}{
+		"density": float64SliceToJS(f.dens),
+		"u":       float64SliceToJS(f.u),
+		"v":       float64SliceToJS(f.v),
+		"n":       f.n,
+	})
+}
+
+// copyJSFloat64Array copies a flattened JS array into dst, leaving any
+// extra tail of dst untouched if src is shorter.
+func copyJSFloat64Array(src js.Value, dst []float64) {
+	length := src.Length()
+	if length > len(dst) {
+		length = len(dst)
+	}
+	for k := 0; k < length; k++ {
+		dst[k] = src.Index(k).Float()
+	}
+}
+
+// float64SliceToJS converts a []float64 into the []interface{} shape
+// js.ValueOf expects for a JS array.
+func float64SliceToJS(s []float64) []interface{} {
+	out := make([]interface{}, len(s))
+	for i, v := range s {
+		out[i] = v
+	}
+	return out
+}
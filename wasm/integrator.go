@@ -0,0 +1,310 @@
+package main
+
+import (
+	"math"
+	"syscall/js"
+)
+
+// rhsFunc evaluates dy/dt = f(t, y) for a registered ODE system, with
+// params carrying whatever constants that system needs (masses, drag
+// coefficients, ...).
+type rhsFunc func(t float64, y, params []float64) []float64
+
+// odeRegistry holds the built-in right-hand sides goIntegrateODE can
+// select by name. New systems can also be supplied straight from JS (see
+// goIntegrateODE) without touching this registry or rebuilding the WASM
+// binary.
+var odeRegistry = map[string]rhsFunc{
+	"gravity":        rhsConstantGravity,
+	"projectileDrag": rhsProjectileWithDrag,
+	"pendulum":       rhsPendulum,
+	"nbody":          rhsNBody,
+}
+
+// rhsConstantGravity: state [x, y, vx, vy], params [g].
+func rhsConstantGravity(t float64, y, params []float64) []float64 {
+	g := params[0]
+	return []float64{y[2], y[3], 0, -g}
+}
+
+// rhsProjectileWithDrag: state [x, y, vx, vy], params [g, dragCoeff]
+// where dragCoeff already folds in 0.5*rho*Cd*A/m.
+func rhsProjectileWithDrag(t float64, y, params []float64) []float64 {
+	g, k := params[0], params[1]
+	vx, vy := y[2], y[3]
+	speed := math.Sqrt(vx*vx + vy*vy)
+	return []float64{vx, vy, -k * speed * vx, -g - k*speed*vy}
+}
+
+// rhsPendulum: state [theta, omega], params [g, length, damping].
+func rhsPendulum(t float64, y, params []float64) []float64 {
+	g, length, damping := params[0], params[1], params[2]
+	theta, omega := y[0], y[1]
+	return []float64{omega, -(g/length)*math.Sin(theta) - damping*omega}
+}
+
+// rhsNBody: state is a flattened [x,y,vx,vy] block per body, params is
+// [G, m1, m2, ...].
+func rhsNBody(t float64, y, params []float64) []float64 {
+	G := params[0]
+	masses := params[1:]
+	n := len(masses)
+	dy := make([]float64, len(y))
+
+	for i := 0; i < n; i++ {
+		xi, yi := y[4*i], y[4*i+1]
+		vxi, vyi := y[4*i+2], y[4*i+3]
+		dy[4*i] = vxi
+		dy[4*i+1] = vyi
+
+		var ax, ay float64
+		for j := 0; j < n; j++ {
+			if j == i {
+				continue
+			}
+			xj, yj := y[4*j], y[4*j+1]
+			dx := xj - xi
+			dyy := yj - yi
+			r2 := dx*dx + dyy*dyy
+			r := math.Sqrt(r2)
+			if r == 0 {
+				continue
+			}
+			a := G * masses[j] / (r2 * r)
+			ax += a * dx
+			ay += a * dyy
+		}
+		dy[4*i+2] = ax
+		dy[4*i+3] = ay
+	}
+
+	return dy
+}
+
+// Cash-Karp RKCK54 coefficients (Cash & Karp, 1990).
+var (
+	ckA  = [6]float64{0, 1.0 / 5, 3.0 / 10, 3.0 / 5, 1, 7.0 / 8}
+	ckB2 = []float64{1.0 / 5}
+	ckB3 = []float64{3.0 / 40, 9.0 / 40}
+	ckB4 = []float64{3.0 / 10, -9.0 / 10, 6.0 / 5}
+	ckB5 = []float64{-11.0 / 54, 5.0 / 2, -70.0 / 27, 35.0 / 27}
+	ckB6 = []float64{1631.0 / 55296, 175.0 / 512, 575.0 / 13824, 44275.0 / 110592, 253.0 / 4096}
+	ckC5 = [6]float64{37.0 / 378, 0, 250.0 / 621, 125.0 / 594, 0, 512.0 / 1771}
+	ckC4 = [6]float64{2825.0 / 27648, 0, 18575.0 / 48384, 13525.0 / 55296, 277.0 / 14336, 1.0 / 4}
+)
+
+// cashKarpStep computes one RKCK54 step of size h from (t, y), returning
+// the 5th-order solution y5, the embedded 4th-order solution y4 (used
+// only for error estimation), and the 6 stage derivatives are not
+// exposed — callers only need y5/y4 to accept/reject and step-size
+// control.
+func cashKarpStep(f rhsFunc, params []float64, t float64, y []float64, h float64) (y5, y4 []float64) {
+	n := len(y)
+	// combine returns y + h * sum(coeffs[s] * ks[s]) for the leading
+	// len(coeffs) stage derivatives in ks.
+	combine := func(ks [][]float64, coeffs []float64) []float64 {
+		out := make([]float64, n)
+		copy(out, y)
+		for s, c := range coeffs {
+			for i := 0; i < n; i++ {
+				out[i] += h * c * ks[s][i]
+			}
+		}
+		return out
+	}
+
+	k1 := f(t, y, params)
+	k2 := f(t+ckA[1]*h, combine([][]float64{k1}, ckB2), params)
+	k3 := f(t+ckA[2]*h, combine([][]float64{k1, k2}, ckB3), params)
+	k4 := f(t+ckA[3]*h, combine([][]float64{k1, k2, k3}, ckB4), params)
+	k5 := f(t+ckA[4]*h, combine([][]float64{k1, k2, k3, k4}, ckB5), params)
+	k6 := f(t+ckA[5]*h, combine([][]float64{k1, k2, k3, k4, k5}, ckB6), params)
+
+	ks := [6][]float64{k1, k2, k3, k4, k5, k6}
+	y5 = make([]float64, n)
+	y4 = make([]float64, n)
+	for i := 0; i < n; i++ {
+		y5[i] = y[i]
+		y4[i] = y[i]
+		for s := 0; s < 6; s++ {
+			y5[i] += h * ckC5[s] * ks[s][i]
+			y4[i] += h * ckC4[s] * ks[s][i]
+		}
+	}
+	return y5, y4
+}
+
+// errNorm computes the RMS of the scaled local error between y5 and y4.
+func errNorm(y5, y4 []float64, absTol, relTol float64) float64 {
+	sum := 0.0
+	for i := range y5 {
+		sc := absTol + relTol*math.Max(math.Abs(y5[i]), math.Abs(y4[i]))
+		if sc == 0 {
+			sc = absTol
+		}
+		e := (y5[i] - y4[i]) / sc
+		sum += e * e
+	}
+	return math.Sqrt(sum / float64(len(y5)))
+}
+
+// integrateAdaptive runs the embedded RKCK54 method from t0 to tEnd with
+// embedded-pair (y5 - y4) error control, optionally stopping early at a sign
+// change of state component eventIndex (bisecting the last accepted step
+// to refine the crossing time). eventIndex < 0 disables event detection.
+func integrateAdaptive(f rhsFunc, params []float64, y0 []float64, t0, tEnd, absTol, relTol, hInit float64, eventIndex int) (times []float64, states [][]float64, eventTime float64, eventFound bool) {
+	t := t0
+	y := append([]float64{}, y0...)
+	h := hInit
+	if h <= 0 {
+		h = (tEnd - t0) / 100
+	}
+
+	times = append(times, t)
+	states = append(states, append([]float64{}, y...))
+
+	const maxSteps = 100000
+	for step := 0; step < maxSteps && t < tEnd; step++ {
+		if t+h > tEnd {
+			h = tEnd - t
+		}
+
+		y5, y4 := cashKarpStep(f, params, t, y, h)
+		en := errNorm(y5, y4, absTol, relTol)
+
+		if en <= 1 || h < 1e-12 {
+			tPrev, yPrev := t, y
+			t += h
+			y = y5
+
+			if eventIndex >= 0 && len(yPrev) > eventIndex && yPrev[eventIndex] != 0 &&
+				sign(yPrev[eventIndex]) != sign(y[eventIndex]) {
+				eventTime = bisectEvent(f, params, tPrev, yPrev, t, h, eventIndex, absTol, relTol)
+				eventFound = true
+				times = append(times, eventTime)
+				states = append(states, y)
+				return times, states, eventTime, eventFound
+			}
+
+			times = append(times, t)
+			states = append(states, append([]float64{}, y...))
+
+			if en > 0 {
+				h *= math.Min(5, 0.9*math.Pow(en, -1.0/5.0))
+			} else {
+				h *= 5
+			}
+		} else {
+			h *= math.Max(0.1, 0.9*math.Pow(en, -1.0/4.0))
+		}
+	}
+
+	return times, states, 0, false
+}
+
+func sign(x float64) int {
+	if x > 0 {
+		return 1
+	}
+	if x < 0 {
+		return -1
+	}
+	return 0
+}
+
+// bisectEvent refines the zero-crossing time of state[eventIndex] inside
+// (tPrev, tPrev+hFull] by bisection, re-integrating a single Cash-Karp
+// step of the trial sub-length each time.
+func bisectEvent(f rhsFunc, params []float64, tPrev float64, yPrev []float64, tFull, hFull float64, eventIndex int, absTol, relTol float64) float64 {
+	lo, hi := 0.0, hFull
+	for iter := 0; iter < 40; iter++ {
+		mid := 0.5 * (lo + hi)
+		y5, _ := cashKarpStep(f, params, tPrev, yPrev, mid)
+		if sign(y5[eventIndex]) == sign(yPrev[eventIndex]) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+		if hi-lo < 1e-10*math.Max(1, hFull) {
+			break
+		}
+	}
+	return tPrev + 0.5*(lo+hi)
+}
+
+// jsRHSAdapter wraps a JS callback function(t, yArray, paramsArray) ->
+// array as an rhsFunc, so new ODEs can be defined from JS without
+// rebuilding the WASM binary.
+func jsRHSAdapter(fn js.Value) rhsFunc {
+	return func(t float64, y, params []float64) []float64 {
+		result := fn.Invoke(t, float64SliceToJS(y), float64SliceToJS(params))
+		out := make([]float64, result.Length())
+		for i := range out {
+			out[i] = result.Index(i).Float()
+		}
+		return out
+	}
+}
+
+// goIntegrateODE integrates a registered or JS-supplied right-hand-side
+// from t0 to tEnd with the embedded RKCK54 (Cash-Karp) adaptive method.
+//
+// Args: system (string naming a built-in in odeRegistry, or a JS
+// function(t, y, params) -> dy/dt), state0 (Float64Array), params
+// (Float64Array of constants for the system), t0, tEnd, absTol, relTol,
+// hInit (float), eventIndex (int, index into state to watch for a sign
+// change; pass -1 to disable).
+func goIntegrateODE(this js.Value, args []js.Value) interface{} {
+	if len(args) != 9 {
+		return js.ValueOf(map[string]interface{}{
+			"error": "Invalid arguments. Expected: system, state0, params, t0, tEnd, absTol, relTol, hInit, eventIndex",
+		})
+	}
+
+	var rhs rhsFunc
+	if args[0].Type() == js.TypeFunction {
+		rhs = jsRHSAdapter(args[0])
+	} else {
+		name := args[0].String()
+		builtin, ok := odeRegistry[name]
+		if !ok {
+			return js.ValueOf(map[string]interface{}{
+				"error": "Unknown ODE system: " + name,
+			})
+		}
+		rhs = builtin
+	}
+
+	y0 := jsFloat64ArrayToSlice(args[1])
+	params := jsFloat64ArrayToSlice(args[2])
+	t0 := args[3].Float()
+	tEnd := args[4].Float()
+	absTol := args[5].Float()
+	relTol := args[6].Float()
+	hInit := args[7].Float()
+	eventIndex := args[8].Int()
+
+	times, states, eventTime, eventFound := integrateAdaptive(rhs, params, y0, t0, tEnd, absTol, relTol, hInit, eventIndex)
+
+	jsStates := make([]interface{}, len(states))
+	for i, s := range states {
+		jsStates[i] = float64SliceToJS(s)
+	}
+
+	return js.ValueOf(map[string]interface{}{
+		"times":      float64SliceToJS(times),
+		"states":     jsStates,
+		"eventFound": eventFound,
+		"eventTime":  eventTime,
+	})
+}
+
+// jsFloat64ArrayToSlice converts a flattened JS array into a fresh
+// []float64 (unlike copyJSFloat64Array, which fills an existing buffer).
+func jsFloat64ArrayToSlice(v js.Value) []float64 {
+	out := make([]float64, v.Length())
+	for i := range out {
+		out[i] = v.Index(i).Float()
+	}
+	return out
+}
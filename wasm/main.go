@@ -29,13 +29,22 @@ func main() {
 	js.Global().Set("goCalculateGravitationalForce", js.FuncOf(calculateGravitationalForce))
 	js.Global().Set("goCalculateOrbit", js.FuncOf(calculateOrbit))
 	js.Global().Set("goCalculateProjectileTrajectory", js.FuncOf(calculateProjectileTrajectory))
+	js.Global().Set("goIntegrateODE", js.FuncOf(goIntegrateODE))
 
 	// Register fluid dynamics functions
-	js.Global().Set("goCalculateFluidFlow", js.FuncOf(calculateFluidFlow))
+	js.Global().Set("goFluidInit", js.FuncOf(goFluidInit))
+	js.Global().Set("goFluidStep", js.FuncOf(goFluidStep))
 	js.Global().Set("goCalculateDrag", js.FuncOf(calculateDragForce))
-	js.Global().Set("goSimulateWave", js.FuncOf(simulateWave))
+	js.Global().Set("goWaveInit", js.FuncOf(goWaveInit))
+	js.Global().Set("goWaveStep", js.FuncOf(goWaveStep))
+	js.Global().Set("goShallowWaterInit", js.FuncOf(goShallowWaterInit))
+	js.Global().Set("goShallowWaterStep", js.FuncOf(goShallowWaterStep))
 	js.Global().Set("goUpdateDOMElement", js.FuncOf(updateDOMElement))
 
+	// Register rigid body / contact solver functions
+	js.Global().Set("goBodyCreate", js.FuncOf(goBodyCreate))
+	js.Global().Set("goWorldStep", js.FuncOf(goWorldStep))
+
 	// Keep the program running
 	<-make(chan bool)
 }
@@ -128,70 +137,6 @@ func calculateProjectileTrajectory(this js.Value, args []js.Value) interface{} {
 	})
 }
 
-// calculateFluidFlow calculates fluid velocities in a 2D grid
-func calculateFluidFlow(this js.Value, args []js.Value) interface{} {
-	if len(args) != 5 {
-		return js.ValueOf(map[string]interface{}{
-			"error": "Invalid arguments. Expected: density, viscosity, gridSize, timeStep, forces",
-		})
-	}
-
-	density := args[0].Float()
-	viscosity := args[1].Float()
-	gridSize := args[2].Int()
-	dt := args[3].Float()
-
-	// Process forces array
-	forces := make([][]float64, gridSize)
-	for i := range forces {
-		forces[i] = make([]float64, gridSize)
-		for j := 0; j < gridSize; j++ {
-			if j < args[4].Length() && i < args[4].Index(j).Length() {
-				forces[i][j] = args[4].Index(j).Index(i).Float()
-			}
-		}
-	}
-
-	// Simulate simple diffusion
-	result := make([]interface{}, gridSize)
-	for i := 0; i < gridSize; i++ {
-		row := make([]interface{}, gridSize)
-		for j := 0; j < gridSize; j++ {
-			// Simple diffusion equation
-			diffusion := 0.0
-			count := 0
-
-			for ni := i - 1; ni <= i+1; ni++ {
-				for nj := j - 1; nj <= j+1; nj++ {
-					if ni >= 0 && ni < gridSize && nj >= 0 && nj < gridSize && !(ni == i && nj == j) {
-						diffusion += forces[ni][nj]
-						count++
-					}
-				}
-			}
-
-			if count > 0 {
-				diffusion /= float64(count)
-			}
-
-			// Update cell with diffusion and viscosity effects
-			value := forces[i][j] + (diffusion-forces[i][j])*viscosity*dt
-
-			// Apply density factor
-			value *= density
-
-			row[j] = value
-		}
-		result[i] = row
-	}
-
-	return js.ValueOf(map[string]interface{}{
-		"velocities": result,
-		"maxValue":   findMaxValue(result),
-		"minValue":   findMinValue(result),
-	})
-}
-
 // calculateDragForce calculates the drag force on an object in a fluid
 func calculateDragForce(this js.Value, args []js.Value) interface{} {
 	if len(args) != 5 {
@@ -227,42 +172,6 @@ func calculateDragForce(this js.Value, args []js.Value) interface{} {
 	})
 }
 
-// simulateWave generates wave propagation data
-func simulateWave(this js.Value, args []js.Value) interface{} {
-	if len(args) != 5 {
-		return js.ValueOf(map[string]interface{}{
-			"error": "Invalid arguments. Expected: amplitude, frequency, damping, resolution, time",
-		})
-	}
-
-	amplitude := args[0].Float()
-	frequency := args[1].Float()
-	damping := args[2].Float()
-	resolution := args[3].Int()
-	time := args[4].Float()
-
-	points := make([]interface{}, resolution)
-
-	for i := 0; i < resolution; i++ {
-		x := float64(i) / float64(resolution) * 2 * math.Pi
-
-		// Calculate wave height with damping
-		distance := math.Abs(x - math.Pi)
-		dampingFactor := math.Exp(-damping * distance)
-		y := amplitude * math.Sin(frequency*x+time) * dampingFactor
-
-		points[i] = map[string]interface{}{
-			"x": x,
-			"y": y,
-		}
-	}
-
-	return js.ValueOf(map[string]interface{}{
-		"waveData": points,
-		"timeStep": time,
-	})
-}
-
 // updateDOMElement demonstrates how Go can update the DOM
 func updateDOMElement(this js.Value, args []js.Value) interface{} {
 	if len(args) != 2 {
@@ -339,34 +248,3 @@ func updateDOMElement(this js.Value, args []js.Value) interface{} {
 		})
 	}
 }
-
-// Helper functions
-func findMaxValue(data []interface{}) float64 {
-	max := -math.MaxFloat64
-
-	for _, row := range data {
-		rowArray := row.([]interface{})
-		for _, val := range rowArray {
-			if val.(float64) > max {
-				max = val.(float64)
-			}
-		}
-	}
-
-	return max
-}
-
-func findMinValue(data []interface{}) float64 {
-	min := math.MaxFloat64
-
-	for _, row := range data {
-		rowArray := row.([]interface{})
-		for _, val := range rowArray {
-			if val.(float64) < min {
-				min = val.(float64)
-			}
-		}
-	}
-
-	return min
-}
@@ -0,0 +1,258 @@
+package main
+
+import "syscall/js"
+
+// gravitationalAccel is the constant g used by the shallow-water solver.
+const gravitationalAccel = 9.81
+
+// shallowWaterSolver advances the 2D nonlinear shallow-water equations
+//
+//	dt h      = -dx(hu) - dy(hv)
+//	dt(hu)    = -dx(hu^2/h + g h^2/2) - dy(hu hv/h) - g h dx(b)
+//	dt(hv)    = -dx(hu hv/h) - dy(hv^2/h + g h^2/2) - g h dy(b)
+//
+// on an nx x ny grid, flattened row-major, using a 2nd-order
+// summation-by-parts (SBP) operator for dx/dy and classical RK4 in time.
+// Wall boundaries are enforced weakly via SAT penalties on the normal
+// momentum component at each edge.
+type shallowWaterSolver struct {
+	nx, ny int
+	dx, dy float64
+	tau    float64 // SAT penalty strength
+
+	bathy      []float64 // b(x,y)
+	bathyGradX []float64
+	bathyGradY []float64
+
+	h, hu, hv []float64
+}
+
+func newShallowWaterSolver(nx, ny int, dx, dy, tau float64) *shallowWaterSolver {
+	size := nx * ny
+	return &shallowWaterSolver{
+		nx: nx, ny: ny,
+		dx: dx, dy: dy,
+		tau:        tau,
+		bathy:      make([]float64, size),
+		bathyGradX: make([]float64, size),
+		bathyGradY: make([]float64, size),
+		h:          make([]float64, size),
+		hu:         make([]float64, size),
+		hv:         make([]float64, size),
+	}
+}
+
+func (s *shallowWaterSolver) idx(i, j int) int {
+	return i + s.nx*j
+}
+
+// sbpDx applies the SBP D = H^-1 Q operator along x: central differences
+// in the interior, one-sided at i==0 and i==nx-1 so that Q + Q^T =
+// diag(-1,0,...,0,1) holds exactly on each row.
+func (s *shallowWaterSolver) sbpDx(f, out []float64) {
+	nx, ny := s.nx, s.ny
+	for j := 0; j < ny; j++ {
+		out[s.idx(0, j)] = (f[s.idx(1, j)] - f[s.idx(0, j)]) / s.dx
+		for i := 1; i < nx-1; i++ {
+			out[s.idx(i, j)] = (f[s.idx(i+1, j)] - f[s.idx(i-1, j)]) / (2 * s.dx)
+		}
+		out[s.idx(nx-1, j)] = (f[s.idx(nx-1, j)] - f[s.idx(nx-2, j)]) / s.dx
+	}
+}
+
+// sbpDy is the y-direction counterpart of sbpDx.
+func (s *shallowWaterSolver) sbpDy(f, out []float64) {
+	nx, ny := s.nx, s.ny
+	for i := 0; i < nx; i++ {
+		out[s.idx(i, 0)] = (f[s.idx(i, 1)] - f[s.idx(i, 0)]) / s.dy
+		for j := 1; j < ny-1; j++ {
+			out[s.idx(i, j)] = (f[s.idx(i, j+1)] - f[s.idx(i, j-1)]) / (2 * s.dy)
+		}
+		out[s.idx(i, ny-1)] = (f[s.idx(i, ny-1)] - f[s.idx(i, ny-2)]) / s.dy
+	}
+}
+
+// satTargets describes the prescribed normal momentum at each wall; 0
+// reflects (no-flow wall), non-zero drives a wavemaker signal.
+type satTargets struct {
+	left, right, bottom, top float64
+}
+
+// rhs evaluates d/dt (h, hu, hv) at the given state, including SAT wall
+// penalties on the normal momentum component at each of the four edges.
+func (s *shallowWaterSolver) rhs(h, hu, hv []float64, targets satTargets) (dh, dhu, dhv []float64) {
+	n := len(h)
+	fluxH_x := make([]float64, n)
+	fluxH_y := make([]float64, n)
+	fluxHu_x := make([]float64, n)
+	fluxHu_y := make([]float64, n)
+	fluxHv_x := make([]float64, n)
+	fluxHv_y := make([]float64, n)
+
+	for k := 0; k < n; k++ {
+		u, v := 0.0, 0.0
+		if h[k] != 0 {
+			u = hu[k] / h[k]
+			v = hv[k] / h[k]
+		}
+		fluxH_x[k] = hu[k]
+		fluxH_y[k] = hv[k]
+		fluxHu_x[k] = hu[k]*u + 0.5*gravitationalAccel*h[k]*h[k]
+		fluxHu_y[k] = hu[k] * v
+		fluxHv_x[k] = hv[k] * u
+		fluxHv_y[k] = hv[k]*v + 0.5*gravitationalAccel*h[k]*h[k]
+	}
+
+	dFluxHx := make([]float64, n)
+	dFluxHy := make([]float64, n)
+	dFluxHux := make([]float64, n)
+	dFluxHuy := make([]float64, n)
+	dFluxHvx := make([]float64, n)
+	dFluxHvy := make([]float64, n)
+
+	s.sbpDx(fluxH_x, dFluxHx)
+	s.sbpDy(fluxH_y, dFluxHy)
+	s.sbpDx(fluxHu_x, dFluxHux)
+	s.sbpDy(fluxHu_y, dFluxHuy)
+	s.sbpDx(fluxHv_x, dFluxHvx)
+	s.sbpDy(fluxHv_y, dFluxHvy)
+
+	dh = make([]float64, n)
+	dhu = make([]float64, n)
+	dhv = make([]float64, n)
+	for k := 0; k < n; k++ {
+		dh[k] = -dFluxHx[k] - dFluxHy[k]
+		dhu[k] = -dFluxHux[k] - dFluxHuy[k] - gravitationalAccel*h[k]*s.bathyGradX[k]
+		dhv[k] = -dFluxHvx[k] - dFluxHvy[k] - gravitationalAccel*h[k]*s.bathyGradY[k]
+	}
+
+	// SAT penalties: drive the normal momentum at each wall toward its
+	// target, scaled by H^-1 at the boundary rows/columns. The SBP21 norm
+	// paired with this narrow-stencil D is H = dx*diag(1/2,1,...,1,1/2),
+	// so H^-1 is 2/dx (resp. 2/dy) at the boundary nodes, not 1/dx.
+	hxInv := 2.0 / s.dx
+	hyInv := 2.0 / s.dy
+	for j := 0; j < s.ny; j++ {
+		dhu[s.idx(0, j)] -= s.tau * hxInv * (hu[s.idx(0, j)] - targets.left)
+		dhu[s.idx(s.nx-1, j)] -= s.tau * hxInv * (hu[s.idx(s.nx-1, j)] - targets.right)
+	}
+	for i := 0; i < s.nx; i++ {
+		dhv[s.idx(i, 0)] -= s.tau * hyInv * (hv[s.idx(i, 0)] - targets.bottom)
+		dhv[s.idx(i, s.ny-1)] -= s.tau * hyInv * (hv[s.idx(i, s.ny-1)] - targets.top)
+	}
+
+	return dh, dhu, dhv
+}
+
+// step advances the state by dt using classical RK4.
+func (s *shallowWaterSolver) step(dt float64, targets satTargets) {
+	n := len(s.h)
+	combine := func(a, k []float64, scale float64) []float64 {
+		out := make([]float64, n)
+		for i := range out {
+			out[i] = a[i] + scale*k[i]
+		}
+		return out
+	}
+
+	k1h, k1hu, k1hv := s.rhs(s.h, s.hu, s.hv, targets)
+
+	h2 := combine(s.h, k1h, dt/2)
+	hu2 := combine(s.hu, k1hu, dt/2)
+	hv2 := combine(s.hv, k1hv, dt/2)
+	k2h, k2hu, k2hv := s.rhs(h2, hu2, hv2, targets)
+
+	h3 := combine(s.h, k2h, dt/2)
+	hu3 := combine(s.hu, k2hu, dt/2)
+	hv3 := combine(s.hv, k2hv, dt/2)
+	k3h, k3hu, k3hv := s.rhs(h3, hu3, hv3, targets)
+
+	h4 := combine(s.h, k3h, dt)
+	hu4 := combine(s.hu, k3hu, dt)
+	hv4 := combine(s.hv, k3hv, dt)
+	k4h, k4hu, k4hv := s.rhs(h4, hu4, hv4, targets)
+
+	for i := 0; i < n; i++ {
+		s.h[i] += dt / 6 * (k1h[i] + 2*k2h[i] + 2*k3h[i] + k4h[i])
+		s.hu[i] += dt / 6 * (k1hu[i] + 2*k2hu[i] + 2*k3hu[i] + k4hu[i])
+		s.hv[i] += dt / 6 * (k1hv[i] + 2*k2hv[i] + 2*k3hv[i] + k4hv[i])
+	}
+}
+
+var theShallowWaterSolver *shallowWaterSolver
+
+// goShallowWaterInit builds the solver state for an nx x ny grid: spacing,
+// SAT penalty strength, bathymetry, and its precomputed gradient.
+//
+// Args: nx, ny (int), dx, dy, tau (float), bathymetry, initialH,
+// initialHu, initialHv (flattened row-major Float64Arrays of length
+// nx*ny).
+func goShallowWaterInit(this js.Value, args []js.Value) interface{} {
+	if len(args) != 9 {
+		return js.ValueOf(map[string]interface{}{
+			"error": "Invalid arguments. Expected: nx, ny, dx, dy, tau, bathymetry, initialH, initialHu, initialHv",
+		})
+	}
+
+	nx := args[0].Int()
+	ny := args[1].Int()
+	dx := args[2].Float()
+	dy := args[3].Float()
+	tau := args[4].Float()
+
+	s := newShallowWaterSolver(nx, ny, dx, dy, tau)
+	copyJSFloat64Array(args[5], s.bathy)
+	copyJSFloat64Array(args[6], s.h)
+	copyJSFloat64Array(args[7], s.hu)
+	copyJSFloat64Array(args[8], s.hv)
+
+	s.sbpDx(s.bathy, s.bathyGradX)
+	s.sbpDy(s.bathy, s.bathyGradY)
+
+	theShallowWaterSolver = s
+
+	return js.ValueOf(map[string]interface{}{
+		"success": true,
+		"nx":      nx,
+		"ny":      ny,
+	})
+}
+
+// goShallowWaterStep advances the solver by dt with classical RK4 and
+// returns the updated (h, hu, hv) fields. Each wall's SAT target is the
+// prescribed normal momentum there; pass 0 for a reflecting wall or a
+// time-varying value to drive a wavemaker.
+//
+// Args: dt (float), huTargetLeft, huTargetRight, hvTargetBottom,
+// hvTargetTop (float).
+func goShallowWaterStep(this js.Value, args []js.Value) interface{} {
+	if theShallowWaterSolver == nil {
+		return js.ValueOf(map[string]interface{}{
+			"error": "goShallowWaterInit must be called before goShallowWaterStep",
+		})
+	}
+	if len(args) != 5 {
+		return js.ValueOf(map[string]interface{}{
+			"error": "Invalid arguments. Expected: dt, huTargetLeft, huTargetRight, hvTargetBottom, hvTargetTop",
+		})
+	}
+
+	s := theShallowWaterSolver
+	dt := args[0].Float()
+	targets := satTargets{
+		left:   args[1].Float(),
+		right:  args[2].Float(),
+		bottom: args[3].Float(),
+		top:    args[4].Float(),
+	}
+
+	s.step(dt, targets)
+
+	return js.ValueOf(map[string]interface{}{
+		"h":  float64SliceToJS(s.h),
+		"hu": float64SliceToJS(s.hu),
+		"hv": float64SliceToJS(s.hv),
+		"nx": s.nx,
+		"ny": s.ny,
+	})
+}
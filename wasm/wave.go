@@ -0,0 +1,230 @@
+package main
+
+import (
+	"math"
+	"syscall/js"
+)
+
+// wavemaker signal types for waveSolver.wavemakerType.
+const (
+	wavemakerPiston   = 0 // monochromatic sine piston/flap
+	wavemakerSolitary = 1 // sech^2 solitary pulse
+	wavemakerExternal = 2 // caller-supplied time series, one sample per step
+)
+
+// waveSolver advances the damped, driven 1D wave equation
+//
+//	eta_tt = c^2 eta_xx - gamma eta_t
+//
+// on an nx-point grid with leapfrog time stepping, a programmable
+// wavemaker at the left boundary, and an absorbing sponge layer at the
+// right boundary. There is no interior source term: the wavemaker is the
+// only forcing.
+type waveSolver struct {
+	nx      int
+	dx      float64
+	c       float64
+	damping float64
+
+	wavemakerType               int
+	amplitude, frequency, phase float64
+
+	nsponge int
+
+	t    float64
+	eta  []float64
+	prev []float64
+}
+
+func newWaveSolver(nx int, dx, c, damping float64, wavemakerType int, amplitude, frequency, phase float64, nsponge int) *waveSolver {
+	return &waveSolver{
+		nx:            nx,
+		dx:            dx,
+		c:             c,
+		damping:       damping,
+		wavemakerType: wavemakerType,
+		amplitude:     amplitude,
+		frequency:     frequency,
+		phase:         phase,
+		nsponge:       nsponge,
+		eta:           make([]float64, nx),
+		prev:          make([]float64, nx),
+	}
+}
+
+// wavemakerSignal returns the driven left-boundary elevation at time t.
+// externalSample is only consulted when wavemakerType is
+// wavemakerExternal.
+func (w *waveSolver) wavemakerSignal(t float64, externalSample float64) float64 {
+	switch w.wavemakerType {
+	case wavemakerSolitary:
+		// sech^2 pulse of characteristic width set by frequency (1/width),
+		// centered so it sweeps past x=0 around t=phase/c.
+		arg := w.frequency * (w.c*t - w.phase)
+		return w.amplitude / (math.Cosh(arg) * math.Cosh(arg))
+	case wavemakerExternal:
+		return externalSample
+	default: // wavemakerPiston
+		return w.amplitude * math.Sin(w.frequency*t-w.phase)
+	}
+}
+
+// spongeSigma grows quadratically from 0 at the start of the sponge layer
+// to sigmaMax at the last grid point, per the standard absorbing-layer
+// ramp used to keep outgoing waves from reflecting off the domain edge.
+func (w *waveSolver) spongeSigma(i int) float64 {
+	const sigmaMax = 2.0
+	start := w.nx - w.nsponge
+	if w.nsponge <= 0 || i < start {
+		return 0
+	}
+	frac := 1.0
+	if w.nsponge > 1 {
+		frac = float64(i-start) / float64(w.nsponge-1)
+	}
+	return sigmaMax * frac * frac
+}
+
+// step advances the solver by one leapfrog update of size dt, driving the
+// left boundary with the wavemaker signal and damping the sponge layer.
+func (w *waveSolver) step(dt float64, externalSample float64) {
+	n := w.nx
+	r := (w.c * dt / w.dx)
+	r2 := r * r
+
+	next := make([]float64, n)
+	for i := 1; i < n-1; i++ {
+		next[i] = 2*w.eta[i] - w.prev[i] +
+			r2*(w.eta[i+1]-2*w.eta[i]+w.eta[i-1]) -
+			w.damping*dt*(w.eta[i]-w.prev[i])
+	}
+	// right edge: one-sided Laplacian so the sponge layer has a value to
+	// damp instead of leaving the last node frozen at 0.
+	next[n-1] = 2*w.eta[n-1] - w.prev[n-1] +
+		r2*(w.eta[n-2]-w.eta[n-1]) -
+		w.damping*dt*(w.eta[n-1]-w.prev[n-1])
+
+	w.t += dt
+	next[0] = w.wavemakerSignal(w.t, externalSample)
+
+	for i := 0; i < n; i++ {
+		sigma := w.spongeSigma(i)
+		if sigma > 0 {
+			ramp := math.Exp(-sigma * dt)
+			next[i] *= ramp
+		}
+	}
+
+	w.prev = w.eta
+	w.eta = next
+}
+
+// surfaceSlope returns d(eta)/dx at each grid point: centered differences
+// in the interior, one-sided at the two end points.
+func (w *waveSolver) surfaceSlope() []float64 {
+	n := w.nx
+	slope := make([]float64, n)
+	slope[0] = (w.eta[1] - w.eta[0]) / w.dx
+	for i := 1; i < n-1; i++ {
+		slope[i] = (w.eta[i+1] - w.eta[i-1]) / (2 * w.dx)
+	}
+	slope[n-1] = (w.eta[n-1] - w.eta[n-2]) / w.dx
+	return slope
+}
+
+// energy returns the total (kinetic + potential) energy of the current
+// state, approximating eta_t with the last leapfrog step.
+func (w *waveSolver) energy(dt float64) float64 {
+	slope := w.surfaceSlope()
+	total := 0.0
+	for i := 0; i < w.nx; i++ {
+		etaT := 0.0
+		if dt > 0 {
+			etaT = (w.eta[i] - w.prev[i]) / dt
+		}
+		total += 0.5*etaT*etaT + 0.5*w.c*w.c*slope[i]*slope[i]
+	}
+	return total * w.dx
+}
+
+var theWaveSolver *waveSolver
+
+// goWaveInit allocates the wave solver and sets its wavemaker/sponge
+// parameters. The grid starts at rest (eta == 0 everywhere).
+//
+// Args: nx (int), dx, c, damping (float), wavemakerType (int: 0 =
+// piston/flap sine, 1 = solitary sech^2 pulse, 2 = external sample
+// series), amplitude, frequency, phase (float, meaning depends on
+// wavemakerType), nsponge (int, width of the absorbing layer at the
+// right edge).
+func goWaveInit(this js.Value, args []js.Value) interface{} {
+	if len(args) != 9 {
+		return js.ValueOf(map[string]interface{}{
+			"error": "Invalid arguments. Expected: nx, dx, c, damping, wavemakerType, amplitude, frequency, phase, nsponge",
+		})
+	}
+
+	nx := args[0].Int()
+	dx := args[1].Float()
+	c := args[2].Float()
+	damping := args[3].Float()
+	wavemakerType := args[4].Int()
+	amplitude := args[5].Float()
+	frequency := args[6].Float()
+	phase := args[7].Float()
+	nsponge := args[8].Int()
+
+	theWaveSolver = newWaveSolver(nx, dx, c, damping, wavemakerType, amplitude, frequency, phase, nsponge)
+
+	return js.ValueOf(map[string]interface{}{
+		"success": true,
+		"nx":      nx,
+	})
+}
+
+// goWaveStep advances the solver by `steps` leapfrog updates of size dt
+// and returns the resulting surface elevation plus derived slope and
+// energy. Violates the CFL condition (c*dt/dx >= 1) returns an error
+// instead of stepping, since the scheme is unconditionally unstable past
+// that point.
+//
+// Args: dt (float), steps (int), externalSamples (Float64Array, only
+// consulted when wavemakerType == 2; one entry consumed per step).
+func goWaveStep(this js.Value, args []js.Value) interface{} {
+	if theWaveSolver == nil {
+		return js.ValueOf(map[string]interface{}{
+			"error": "goWaveInit must be called before goWaveStep",
+		})
+	}
+	if len(args) != 3 {
+		return js.ValueOf(map[string]interface{}{
+			"error": "Invalid arguments. Expected: dt, steps, externalSamples",
+		})
+	}
+
+	w := theWaveSolver
+	dt := args[0].Float()
+	steps := args[1].Int()
+	externalSamples := jsFloat64ArrayToSlice(args[2])
+
+	if w.c*dt/w.dx >= 1 {
+		return js.ValueOf(map[string]interface{}{
+			"error": "CFL condition violated: c*dt/dx must be < 1",
+		})
+	}
+
+	for s := 0; s < steps; s++ {
+		sample := 0.0
+		if s < len(externalSamples) {
+			sample = externalSamples[s]
+		}
+		w.step(dt, sample)
+	}
+
+	return js.ValueOf(map[string]interface{}{
+		"eta":    float64SliceToJS(w.eta),
+		"slope":  float64SliceToJS(w.surfaceSlope()),
+		"energy": w.energy(dt),
+		"t":      w.t,
+	})
+}